@@ -0,0 +1,84 @@
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"unsafe"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// ChangePDFPassword re-keys an already-encrypted PDF in one call: it
+// decrypts with currentPwStr (tried as both user and owner password, like
+// DecryptPDF), then re-encrypts with the new user/owner passwords. configStr
+// takes the same form as EncryptPDF's permissionsStr; when it doesn't
+// specify a permission mask, the source document's existing permissions are
+// carried over instead of being reset. The result is returned as a base64
+// encoded C string, matching EncryptPDF/DecryptPDF.
+//
+//export ChangePDFPassword
+func ChangePDFPassword(
+	pdfBufferPtr *C.uchar, pdfBufferSize C.int,
+	currentPwStr *C.char,
+	newUserPwStr *C.char, newOwnerPwStr *C.char,
+	configStr *C.char,
+) *C.char {
+	pdfBytes := C.GoBytes(unsafe.Pointer(pdfBufferPtr), pdfBufferSize)
+	currentPw := C.GoString(currentPwStr)
+	newUserPw := C.GoString(newUserPwStr)
+	newOwnerPw := C.GoString(newOwnerPwStr)
+	configJSON := C.GoString(configStr)
+
+	plaintext, err := decryptWithPassword(pdfBytes, currentPw)
+	if err != nil {
+		return C.CString(fmt.Sprintf("Error decrypting PDF: %v", err))
+	}
+
+	srcMask, err := sourcePermissionMask(pdfBytes)
+	if err != nil {
+		return C.CString(fmt.Sprintf("Error reading source permissions: %v", err))
+	}
+
+	conf, _, hasMask, err := resolveEncryptConfig(newUserPw, newOwnerPw, configJSON)
+	if err != nil {
+		return C.CString(fmt.Sprintf("Error configuring encryption: %v", err))
+	}
+	if !hasMask {
+		conf.Permissions = srcMask
+	}
+
+	r := bytes.NewReader(plaintext)
+	buf := &bytes.Buffer{}
+	if err := api.Encrypt(r, buf, conf); err != nil {
+		return C.CString(fmt.Sprintf("Error encrypting PDF: %v", err))
+	}
+
+	encodedOutput := buf.Bytes()
+	encodedString := make([]byte, base64.StdEncoding.EncodedLen(len(encodedOutput)))
+	base64.StdEncoding.Encode(encodedString, encodedOutput)
+
+	return C.CString(string(encodedString))
+}
+
+// decryptWithPassword decrypts pdfBytes into memory, trying pw as both the
+// user and owner password.
+func decryptWithPassword(pdfBytes []byte, pw string) ([]byte, error) {
+	r := bytes.NewReader(pdfBytes)
+	buf := &bytes.Buffer{}
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = pw
+	conf.OwnerPW = pw
+
+	if err := api.Decrypt(r, buf, conf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
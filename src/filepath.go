@@ -0,0 +1,167 @@
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// fileOpStatus is the JSON status returned by EncryptPDFFile / DecryptPDFFile
+// in place of the document itself.
+type fileOpStatus struct {
+	BytesWritten int64  `json:"bytes_written"`
+	SHA256       string `json:"sha256"`
+	ElapsedMs    int64  `json:"elapsed_ms"`
+	Error        string `json:"error,omitempty"`
+}
+
+func fileOpJSON(status fileOpStatus) *C.char {
+	out, err := json.Marshal(status)
+	if err != nil {
+		return C.CString(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	return C.CString(string(out))
+}
+
+// writeAtomically runs write against a temp file in tmpDir (or alongside
+// outPath when tmpDir is empty) and renames it onto outPath only on success,
+// so a crash or error mid-write never leaves a truncated file at outPath.
+func writeAtomically(outPath, tmpDir string, write func(w io.Writer) error) (int64, string, error) {
+	dir := tmpDir
+	if dir == "" {
+		dir = filepath.Dir(outPath)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".pdf-encrypt-decrypt-*.tmp")
+	if err != nil {
+		return 0, "", fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(tmp, hasher)}
+
+	if err := write(counter); err != nil {
+		tmp.Close()
+		return 0, "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, "", fmt.Errorf("closing temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return 0, "", fmt.Errorf("renaming temp file: %w", err)
+	}
+
+	return counter.n, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// EncryptPDFFile encrypts the PDF at inPathStr and streams the result to
+// outPathStr, avoiding the full-buffer + base64 round trip EncryptPDF
+// requires. It returns a JSON status string rather than the document.
+//
+//export EncryptPDFFile
+func EncryptPDFFile(
+	inPathStr *C.char, outPathStr *C.char,
+	userPwStr *C.char, ownerPwStr *C.char, permissionsStr *C.char,
+	tmpDirStr *C.char,
+) *C.char {
+	start := time.Now()
+
+	inPath := C.GoString(inPathStr)
+	outPath := C.GoString(outPathStr)
+	userPw := C.GoString(userPwStr)
+	ownerPw := C.GoString(ownerPwStr)
+	tmpDir := C.GoString(tmpDirStr)
+
+	in, err := os.Open(inPath)
+	if err != nil {
+		return fileOpJSON(fileOpStatus{Error: fmt.Sprintf("opening input: %v", err)})
+	}
+	defer in.Close()
+
+	conf, _, _, err := resolveEncryptConfig(userPw, ownerPw, C.GoString(permissionsStr))
+	if err != nil {
+		return fileOpJSON(fileOpStatus{Error: fmt.Sprintf("configuring encryption: %v", err)})
+	}
+
+	n, sum, err := writeAtomically(outPath, tmpDir, func(w io.Writer) error {
+		return api.Encrypt(in, w, conf)
+	})
+	if err != nil {
+		return fileOpJSON(fileOpStatus{Error: fmt.Sprintf("encrypting PDF: %v", err)})
+	}
+
+	return fileOpJSON(fileOpStatus{BytesWritten: n, SHA256: sum, ElapsedMs: time.Since(start).Milliseconds()})
+}
+
+// DecryptPDFFile decrypts the PDF at inPathStr and streams the result to
+// outPathStr. It tries the user password first, then the owner password if
+// provided, mirroring DecryptPDF's fallback behavior.
+//
+//export DecryptPDFFile
+func DecryptPDFFile(
+	inPathStr *C.char, outPathStr *C.char,
+	passwordStr *C.char, ownerPwStr *C.char,
+	tmpDirStr *C.char,
+) *C.char {
+	start := time.Now()
+
+	inPath := C.GoString(inPathStr)
+	outPath := C.GoString(outPathStr)
+	password := C.GoString(passwordStr)
+	ownerPw := C.GoString(ownerPwStr)
+	tmpDir := C.GoString(tmpDirStr)
+
+	decrypt := func(pw string) (int64, string, error) {
+		in, err := os.Open(inPath)
+		if err != nil {
+			return 0, "", fmt.Errorf("opening input: %w", err)
+		}
+		defer in.Close()
+
+		conf := model.NewDefaultConfiguration()
+		conf.UserPW = pw
+		conf.OwnerPW = pw
+
+		return writeAtomically(outPath, tmpDir, func(w io.Writer) error {
+			return api.Decrypt(in, w, conf)
+		})
+	}
+
+	n, sum, err := decrypt(password)
+	if err != nil && ownerPw != "" && ownerPw != password {
+		n, sum, err = decrypt(ownerPw)
+	}
+	if err != nil {
+		return fileOpJSON(fileOpStatus{Error: fmt.Sprintf("decrypting PDF: %v", err)})
+	}
+
+	return fileOpJSON(fileOpStatus{BytesWritten: n, SHA256: sum, ElapsedMs: time.Since(start).Milliseconds()})
+}
@@ -0,0 +1,174 @@
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// inspectPermissions is the decoded view of model.PermissionFlags returned
+// by InspectPDF, one bool per named bucket of buildPermissionMask. Print
+// reports the high-resolution print bit (PermissionPrintRev3) only; the
+// low-resolution print bit (PermissionPrintRev2) is not reported.
+type inspectPermissions struct {
+	Print    bool `json:"print"`
+	Copy     bool `json:"copy"`
+	Modify   bool `json:"modify"`
+	Annotate bool `json:"annotate"`
+	Forms    bool `json:"forms"`
+	Assemble bool `json:"assemble"`
+}
+
+// inspectResult is the JSON blob returned by InspectPDF.
+type inspectResult struct {
+	Encrypted      bool               `json:"encrypted"`
+	Algorithm      string             `json:"algorithm,omitempty"`
+	Revision       int                `json:"revision,omitempty"`
+	KeyLength      int                `json:"key_length,omitempty"`
+	Permissions    inspectPermissions `json:"permissions"`
+	RequiresUserPw bool               `json:"requires_user_password"`
+	PageCount      int                `json:"page_count"`
+	PDFVersion     string             `json:"pdf_version"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// encAlgorithm labels ctx's encryption the way the PDF spec and pdfcpu's own
+// CLI output do. V alone doesn't say whether a V=4 handler uses RC4 or AES
+// (both NewRC4Configuration(..., 128) and NewAESConfiguration(..., 128)
+// produce V=4, L=128) — the crypt filter method actually used is recorded
+// on ctx.AES4Streams by the same validateCryptFilters pass that Read() runs
+// for every V=4/5/6 file, so reuse that instead of guessing from (V, L).
+func encAlgorithm(ctx *model.Context) string {
+	v, keyLength := ctx.E.V, ctx.E.L
+	if keyLength == 0 {
+		keyLength = 40
+	}
+
+	if v <= 3 {
+		return fmt.Sprintf("RC4-%d", keyLength)
+	}
+
+	if ctx.AES4Streams {
+		if keyLength >= 256 {
+			return "AES-256"
+		}
+		return "AES-128"
+	}
+
+	return fmt.Sprintf("RC4-%d", keyLength)
+}
+
+// sourcePermissionMask reads back the permission mask an already-encrypted
+// PDF carries, without decrypting it. Used by ChangePDFPassword to preserve
+// the source document's permissions when the caller doesn't specify new
+// ones. InspectPDF needs more than the mask (page count, version,
+// authentication check) so it reads its own model.Context instead of using
+// this helper.
+func sourcePermissionMask(pdfBytes []byte) (model.PermissionFlags, error) {
+	r := bytes.NewReader(pdfBytes)
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := api.ReadContext(r, conf)
+	if err != nil {
+		return 0, fmt.Errorf("reading PDF: %w", err)
+	}
+
+	if ctx.E == nil {
+		return 0, nil
+	}
+
+	return model.PermissionFlags(ctx.E.P), nil
+}
+
+// readContextNoPassword opens pdfBytes with no password, the same way
+// pdfcpu itself treats owner-only-protected files: the owner password is
+// only needed to change permissions, not to view the document, so an empty
+// user/owner password is enough. For a document that also carries a real
+// user (open) password, pdfcpu.ErrWrongPassword comes back wrapped in err;
+// the caller uses that to report requires_user_password without treating
+// it as a hard failure.
+func readContextNoPassword(pdfBytes []byte) (*model.Context, error) {
+	r := bytes.NewReader(pdfBytes)
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	return api.ReadContext(r, conf)
+}
+
+func decodeInspectPermissions(mask model.PermissionFlags) inspectPermissions {
+	return inspectPermissions{
+		Print:    mask&model.PermissionPrintRev3 != 0,
+		Copy:     mask&model.PermissionExtract != 0,
+		Modify:   mask&model.PermissionModify != 0,
+		Annotate: mask&model.PermissionModAnnFillForm != 0,
+		Forms:    mask&model.PermissionFillRev3 != 0,
+		Assemble: mask&model.PermissionAssembleRev3 != 0,
+	}
+}
+
+// InspectPDF opens a PDF read-only and returns a JSON blob describing its
+// encryption (if any), permissions, page count and PDF version, without
+// decrypting the document or requiring a password for owner-only protection.
+//
+// For a PDF that also requires a user (open) password, pdfcpu can't parse
+// far enough to report algorithm/revision/permissions/page-count without
+// that password — in that case InspectPDF reports
+// {encrypted:true, requires_user_password:true} and leaves the rest unset,
+// rather than failing outright.
+//
+//export InspectPDF
+func InspectPDF(pdfBufferPtr *C.uchar, pdfBufferSize C.int) *C.char {
+	pdfBytes := C.GoBytes(unsafe.Pointer(pdfBufferPtr), pdfBufferSize)
+
+	ctx, err := readContextNoPassword(pdfBytes)
+	if err != nil {
+		if errors.Is(err, pdfcpu.ErrWrongPassword) {
+			return inspectJSON(inspectResult{Encrypted: true, RequiresUserPw: true})
+		}
+		return inspectJSON(inspectResult{Error: fmt.Sprintf("reading PDF: %v", err)})
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return inspectJSON(inspectResult{Error: fmt.Sprintf("counting pages: %v", err)})
+	}
+
+	res := inspectResult{
+		PageCount: ctx.PageCount,
+	}
+	if ctx.HeaderVersion != nil {
+		res.PDFVersion = ctx.HeaderVersion.String()
+	}
+
+	enc := ctx.E
+	if enc == nil {
+		return inspectJSON(res)
+	}
+
+	res.Encrypted = true
+	res.Revision = enc.R
+	res.KeyLength = enc.L
+	res.Algorithm = encAlgorithm(ctx)
+	res.Permissions = decodeInspectPermissions(model.PermissionFlags(enc.P))
+
+	return inspectJSON(res)
+}
+
+func inspectJSON(res inspectResult) *C.char {
+	out, err := json.Marshal(res)
+	if err != nil {
+		return C.CString(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	return C.CString(string(out))
+}
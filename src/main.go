@@ -58,7 +58,126 @@ func buildPermissionMask(perms []string) model.PermissionFlags {
 	return mask
 }
 
-// EncryptPDF encrypts a PDF buffer and returns the result as a base64 encoded C string.
+// PermissionConfig is the fine-grained permission form accepted by
+// EncryptPDF's JSON config, one explicit allow/deny per flag the PDF spec
+// distinguishes. A nil field leaves that flag unset (denied); this is how
+// callers separate "print at low resolution" from "print at high
+// resolution" and "fill forms" from "annotate", which buildPermissionMask's
+// named buckets collapse together.
+type PermissionConfig struct {
+	PrintLowRes  *bool `json:"print_low_res,omitempty"`
+	PrintHighRes *bool `json:"print_high_res,omitempty"`
+	Copy         *bool `json:"copy,omitempty"`
+	Modify       *bool `json:"modify,omitempty"`
+	Annotate     *bool `json:"annotate,omitempty"`
+	FillForms    *bool `json:"fill_forms,omitempty"`
+	Assemble     *bool `json:"assemble,omitempty"`
+}
+
+func buildPermissionMaskFromConfig(pc PermissionConfig) model.PermissionFlags {
+	var mask model.PermissionFlags = 0
+
+	allow := func(b *bool) bool { return b != nil && *b }
+
+	if allow(pc.PrintLowRes) {
+		mask |= model.PermissionPrintRev2
+	}
+	if allow(pc.PrintHighRes) {
+		mask |= model.PermissionPrintRev3
+	}
+	if allow(pc.Copy) {
+		mask |= model.PermissionExtract
+	}
+	if allow(pc.Modify) {
+		mask |= model.PermissionModify
+	}
+	if allow(pc.Annotate) {
+		mask |= model.PermissionModAnnFillForm
+	}
+	if allow(pc.FillForms) {
+		mask |= model.PermissionFillRev3
+	}
+	if allow(pc.Assemble) {
+		mask |= model.PermissionAssembleRev3
+	}
+
+	return mask
+}
+
+// encryptConfig is the JSON object form of EncryptPDF's permissionsStr
+// argument, layered on top of the legacy []string form so existing callers
+// keep working unchanged.
+type encryptConfig struct {
+	Algorithm   string            `json:"algorithm"`
+	KeyLength   int               `json:"key_length"`
+	Permissions *PermissionConfig `json:"permissions"`
+}
+
+// resolveEncryptConfig turns EncryptPDF/EncryptPDFFile/ChangePDFPassword's
+// permissionsStr argument into a pdfcpu configuration. permissionsJSON is
+// either the legacy JSON array of named permission buckets, or a JSON
+// object {algorithm, key_length, permissions} for algorithm selection and
+// per-flag permissions. It also reports whether permissionsJSON specified
+// a permission mask at all, so callers that need a fallback (e.g.
+// ChangePDFPassword preserving the source document's permissions) know
+// whether conf.Permissions still needs to be filled in.
+func resolveEncryptConfig(userPw, ownerPw, permissionsJSON string) (conf *model.Configuration, mask model.PermissionFlags, hasMask bool, err error) {
+	algorithm := "aes"
+	keyLength := 256
+
+	trimmed := strings.TrimSpace(permissionsJSON)
+	if strings.HasPrefix(trimmed, "{") {
+		var cfg encryptConfig
+		if err := json.Unmarshal([]byte(trimmed), &cfg); err != nil {
+			return nil, 0, false, fmt.Errorf("parsing config: %w", err)
+		}
+		if cfg.Algorithm != "" {
+			algorithm = strings.ToLower(cfg.Algorithm)
+		}
+		if cfg.KeyLength != 0 {
+			keyLength = cfg.KeyLength
+		}
+		if cfg.Permissions != nil {
+			mask = buildPermissionMaskFromConfig(*cfg.Permissions)
+			hasMask = true
+		}
+	} else {
+		var permissions []string
+		json.Unmarshal([]byte(trimmed), &permissions)
+		if len(permissions) > 0 && !(len(permissions) == 1 && strings.ToLower(permissions[0]) == "default") {
+			mask = buildPermissionMask(permissions)
+			hasMask = true
+		}
+	}
+
+	switch algorithm {
+	case "rc4":
+		if keyLength != 40 && keyLength != 128 {
+			return nil, 0, false, fmt.Errorf("RC4 only supports 40 or 128 bit keys, got %d", keyLength)
+		}
+		conf = model.NewRC4Configuration(userPw, ownerPw, keyLength)
+	case "aes", "":
+		if keyLength != 128 && keyLength != 256 {
+			return nil, 0, false, fmt.Errorf("AES only supports 128 or 256 bit keys, got %d", keyLength)
+		}
+		conf = model.NewAESConfiguration(userPw, ownerPw, keyLength)
+	default:
+		return nil, 0, false, fmt.Errorf("unknown algorithm %q (expected \"rc4\" or \"aes\")", algorithm)
+	}
+
+	if hasMask {
+		conf.Permissions = mask
+	}
+
+	return conf, mask, hasMask, nil
+}
+
+// EncryptPDF encrypts a PDF buffer and returns the result as a base64 encoded
+// C string. permissionsStr is either the legacy JSON array of named
+// permission buckets (e.g. ["print","copy"]) or a JSON object
+// {algorithm, key_length, permissions} selecting "rc4"/"aes" and 40/128/256
+// bit keys, with permissions as a per-flag allow/deny map; see
+// resolveEncryptConfig.
 //
 //export EncryptPDF
 func EncryptPDF(
@@ -69,31 +188,15 @@ func EncryptPDF(
 	userPw := C.GoString(userPwStr)
 	ownerPw := C.GoString(ownerPwStr)
 	permissionsJSON := C.GoString(permissionsStr)
-	var permissions []string
-	json.Unmarshal([]byte(permissionsJSON), &permissions)
 
 	// Convert C buffer pointer and size to Go byte slice
 	pdfBytes := C.GoBytes(unsafe.Pointer(pdfBufferPtr), pdfBufferSize)
 	r := bytes.NewReader(pdfBytes)
 	buf := &bytes.Buffer{}
 
-	// Configure pdfcpu encryption settings
-	keyLength := 256
-	conf := model.NewAESConfiguration(userPw, ownerPw, keyLength)
-
-	// permissions is a []string decoded from JSON; only set custom permissions
-	// when the slice is non-empty and not solely ["default"].
-	setCustomPerms := false
-	if len(permissions) > 0 {
-		if len(permissions) == 1 && strings.ToLower(permissions[0]) == "default" {
-			setCustomPerms = false
-		} else {
-			setCustomPerms = true
-		}
-	}
-
-	if setCustomPerms {
-		conf.Permissions = buildPermissionMask(permissions)
+	conf, _, _, err := resolveEncryptConfig(userPw, ownerPw, permissionsJSON)
+	if err != nil {
+		return C.CString(fmt.Sprintf("Error configuring encryption: %v", err))
 	}
 
 	if err := api.Encrypt(r, buf, conf); err != nil {
@@ -0,0 +1,290 @@
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// RecoverMode selects the password-recovery strategy used by RecoverPDFPassword.
+type RecoverMode string
+
+const (
+	RecoverModeNumeric  RecoverMode = "numeric"
+	RecoverModeWordlist RecoverMode = "wordlist"
+	RecoverModeMask     RecoverMode = "mask"
+)
+
+// recoverConfig mirrors the JSON config accepted by RecoverPDFPassword.
+type recoverConfig struct {
+	Mode        RecoverMode `json:"mode"`
+	Min         int         `json:"min"`
+	Max         int         `json:"max"`
+	Charset     string      `json:"charset"`
+	WordlistB64 string      `json:"wordlist_b64"`
+	Mask        string      `json:"mask"`
+	MaxAttempts int         `json:"max_attempts"`
+	Parallelism int         `json:"parallelism"`
+}
+
+type recoverResult struct {
+	Password string `json:"password,omitempty"`
+	Attempts int64  `json:"attempts"`
+	Found    bool   `json:"found"`
+	Error    string `json:"error,omitempty"`
+}
+
+const (
+	maskDigit = 'd'
+	maskUpper = 'u'
+	maskLower = 'l'
+)
+
+// tryPassword reports whether pw successfully opens pdfBytes. It uses
+// api.ReadContext rather than api.Decrypt: pdfcpu authenticates the
+// password before it dereferences (decompresses/decodes) the rest of the
+// document, so a wrong candidate fails fast without paying for the full
+// object graph walk and without re-serializing a throwaway decrypted copy
+// of the document, the way api.Decrypt would for every candidate. pdfcpu
+// doesn't expose the lower-level "derive the file key once, check many
+// candidate passwords against it" primitive used internally by
+// validateUserPassword/validateOwnerPassword, so this is the cheapest
+// rejection path its public API allows; only the winning candidate pays
+// the full parse cost.
+func tryPassword(pdfBytes []byte, pw string) bool {
+	r := bytes.NewReader(pdfBytes)
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	conf.UserPW = pw
+	conf.OwnerPW = pw
+	_, err := api.ReadContext(r, conf)
+	return err == nil
+}
+
+const defaultNumericCharset = "0123456789"
+
+// numericCandidates streams every string of length min..max drawn from
+// charset (defaulting to the digits 0-9) on ch, in counting order.
+func numericCandidates(ctx context.Context, min, max int, charset string, ch chan<- string) {
+	defer close(ch)
+
+	if charset == "" {
+		charset = defaultNumericCharset
+	}
+	alphabet := []rune(charset)
+
+	for length := min; length <= max; length++ {
+		indices := make([]int, length)
+		for {
+			candidate := make([]rune, length)
+			for i, idx := range indices {
+				candidate[i] = alphabet[idx]
+			}
+			select {
+			case ch <- string(candidate):
+			case <-ctx.Done():
+				return
+			}
+
+			pos := length - 1
+			for pos >= 0 {
+				indices[pos]++
+				if indices[pos] < len(alphabet) {
+					break
+				}
+				indices[pos] = 0
+				pos--
+			}
+			if pos < 0 {
+				break
+			}
+		}
+	}
+}
+
+// wordlistCandidates streams one candidate per non-empty line of wordlist.
+func wordlistCandidates(ctx context.Context, wordlist []byte, ch chan<- string) {
+	defer close(ch)
+	for _, line := range strings.Split(string(wordlist), "\n") {
+		word := strings.TrimRight(line, "\r")
+		if word == "" {
+			continue
+		}
+		select {
+		case ch <- word:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// maskCandidates expands a hashcat-style mask (?d ?u ?l literal runs) into
+// every matching candidate, e.g. "?d?d?d?u?l" -> 000Aa .. 999Zz.
+func maskCandidates(ctx context.Context, mask string, ch chan<- string) {
+	defer close(ch)
+
+	var classes [][]rune
+	runes := []rune(mask)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '?' && i+1 < len(runes) {
+			i++
+			switch runes[i] {
+			case maskDigit:
+				classes = append(classes, []rune("0123456789"))
+			case maskUpper:
+				classes = append(classes, []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+			case maskLower:
+				classes = append(classes, []rune("abcdefghijklmnopqrstuvwxyz"))
+			default:
+				classes = append(classes, []rune{runes[i]})
+			}
+			continue
+		}
+		classes = append(classes, []rune{runes[i]})
+	}
+
+	indices := make([]int, len(classes))
+	for {
+		candidate := make([]rune, len(classes))
+		for i, class := range classes {
+			candidate[i] = class[indices[i]]
+		}
+		select {
+		case ch <- string(candidate):
+		case <-ctx.Done():
+			return
+		}
+
+		pos := len(classes) - 1
+		for pos >= 0 {
+			indices[pos]++
+			if indices[pos] < len(classes[pos]) {
+				break
+			}
+			indices[pos] = 0
+			pos--
+		}
+		if pos < 0 {
+			return
+		}
+	}
+}
+
+// RecoverPDFPassword attempts to recover a lost user/owner password for an
+// encrypted PDF using a numeric brute force, a wordlist, or a mask, and
+// returns the recovered password (or an error) as a JSON string.
+//
+//export RecoverPDFPassword
+func RecoverPDFPassword(
+	pdfBufferPtr *C.uchar, pdfBufferSize C.int,
+	configStr *C.char,
+) *C.char {
+	pdfBytes := C.GoBytes(unsafe.Pointer(pdfBufferPtr), pdfBufferSize)
+
+	var cfg recoverConfig
+	if err := json.Unmarshal([]byte(C.GoString(configStr)), &cfg); err != nil {
+		return recoverJSON(recoverResult{Error: fmt.Sprintf("invalid config: %v", err)})
+	}
+
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = 4
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	candidates := make(chan string, cfg.Parallelism*2)
+
+	switch cfg.Mode {
+	case RecoverModeNumeric:
+		min, max := cfg.Min, cfg.Max
+		if min <= 0 {
+			min = 3
+		}
+		if max < min {
+			max = 6
+		}
+		go numericCandidates(ctx, min, max, cfg.Charset, candidates)
+	case RecoverModeWordlist:
+		wordlist, err := base64.StdEncoding.DecodeString(cfg.WordlistB64)
+		if err != nil {
+			cancel()
+			return recoverJSON(recoverResult{Error: fmt.Sprintf("invalid wordlist_b64: %v", err)})
+		}
+		go wordlistCandidates(ctx, wordlist, candidates)
+	case RecoverModeMask:
+		if cfg.Mask == "" {
+			cancel()
+			return recoverJSON(recoverResult{Error: "mask mode requires a non-empty mask"})
+		}
+		go maskCandidates(ctx, cfg.Mask, candidates)
+	default:
+		cancel()
+		return recoverJSON(recoverResult{Error: fmt.Sprintf("unknown mode: %q", cfg.Mode)})
+	}
+
+	var (
+		attempts int64
+		found    int32
+		result   string
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+	)
+
+	for i := 0; i < cfg.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for candidate := range candidates {
+				n := atomic.AddInt64(&attempts, 1)
+				if cfg.MaxAttempts > 0 && n > int64(cfg.MaxAttempts) {
+					cancel()
+					return
+				}
+				if tryPassword(pdfBytes, candidate) {
+					if atomic.CompareAndSwapInt32(&found, 0, 1) {
+						mu.Lock()
+						result = candidate
+						mu.Unlock()
+						cancel()
+					}
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if atomic.LoadInt32(&found) == 1 {
+		return recoverJSON(recoverResult{Password: result, Attempts: atomic.LoadInt64(&attempts), Found: true})
+	}
+	return recoverJSON(recoverResult{Attempts: atomic.LoadInt64(&attempts), Found: false, Error: "password not found"})
+}
+
+func recoverJSON(res recoverResult) *C.char {
+	out, err := json.Marshal(res)
+	if err != nil {
+		return C.CString(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	return C.CString(string(out))
+}